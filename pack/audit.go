@@ -0,0 +1,65 @@
+/*
+Copyright (C) 2018 Expedia Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pack
+
+import (
+	"net/http"
+	"time"
+)
+
+// AuditSink records pack lifecycle events for operators and compliance. Every method is best
+// effort and must not block the request it's auditing; implementations should not return errors
+// that callers would need to act on - they should log their own failures instead.
+type AuditSink interface {
+	PackCreated(packId, actor, remoteAddr string, at time.Time)
+	PackUpdated(packId, actor, remoteAddr string, at time.Time)
+	PackDeleted(packId, actor, remoteAddr string, at time.Time)
+}
+
+// auditSink is the configured sink, mirroring the packRepo package-level var so tests can inject
+// a mock. It defaults to a no-op until ConfigureAuditSink is called at startup.
+var auditSink AuditSink = noopAuditSink{}
+
+// ConfigureAuditSink sets the sink used for pack lifecycle auditing; called once at startup.
+func ConfigureAuditSink(sink AuditSink) {
+	auditSink = sink
+}
+
+type noopAuditSink struct{}
+
+func (noopAuditSink) PackCreated(packId, actor, remoteAddr string, at time.Time) {}
+func (noopAuditSink) PackUpdated(packId, actor, remoteAddr string, at time.Time) {}
+func (noopAuditSink) PackDeleted(packId, actor, remoteAddr string, at time.Time) {}
+
+// actorFor identifies who made the request, for the audit record. If the authenticating
+// middleware already determined the actor (e.g. AuthenticateRegistration resolving an OIDC
+// subject, which has no pack of its own to fall back to) it is recorded on the request context
+// and takes precedence; otherwise this falls back to the HTTP Basic username if admin
+// credentials were presented, then the pack itself (it authenticated with its own token or a
+// matching OIDC subject), then "unknown".
+func actorFor(r *http.Request, packId string) string {
+	if actor, ok := actorFromContext(r); ok {
+		return actor
+	}
+	if username, _, ok := r.BasicAuth(); ok {
+		return username
+	}
+	if packId != "" {
+		return packId
+	}
+	return "unknown"
+}
@@ -0,0 +1,120 @@
+/*
+Copyright (C) 2018 Expedia Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pack
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/HotelsDotCom/go-logger/logger"
+)
+
+// FileAuditSink writes one JSON object per line per pack lifecycle event, rotating the file
+// before a write that would take it past maxBytes.
+type FileAuditSink struct {
+	path     string
+	maxBytes int64
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// NewFileAuditSink opens (or creates) the JSON-lines audit log at path, rotating it to
+// "<path>.<unix-nano>" once it would exceed maxBytes.
+func NewFileAuditSink(path string, maxBytes int64) (*FileAuditSink, error) {
+	s := &FileAuditSink{path: path, maxBytes: maxBytes}
+	if err := s.openFile(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *FileAuditSink) openFile() error {
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0640)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	s.file = f
+	s.size = info.Size()
+	return nil
+}
+
+type auditRecord struct {
+	Event      string    `json:"event"`
+	PackId     string    `json:"packId"`
+	Actor      string    `json:"actor"`
+	RemoteAddr string    `json:"remoteAddr"`
+	Timestamp  time.Time `json:"timestamp"`
+}
+
+func (s *FileAuditSink) PackCreated(packId, actor, remoteAddr string, at time.Time) {
+	s.write(auditRecord{Event: "pack.created", PackId: packId, Actor: actor, RemoteAddr: remoteAddr, Timestamp: at})
+}
+
+func (s *FileAuditSink) PackUpdated(packId, actor, remoteAddr string, at time.Time) {
+	s.write(auditRecord{Event: "pack.updated", PackId: packId, Actor: actor, RemoteAddr: remoteAddr, Timestamp: at})
+}
+
+func (s *FileAuditSink) PackDeleted(packId, actor, remoteAddr string, at time.Time) {
+	s.write(auditRecord{Event: "pack.deleted", PackId: packId, Actor: actor, RemoteAddr: remoteAddr, Timestamp: at})
+}
+
+func (s *FileAuditSink) write(rec auditRecord) {
+
+	line, err := json.Marshal(rec)
+	if err != nil {
+		logger.Errorf("Cannot marshal audit record: %s", err)
+		return
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.size+int64(len(line)) > s.maxBytes {
+		if err := s.rotate(); err != nil {
+			logger.Errorf("Cannot rotate audit log %s: %s", s.path, err)
+		}
+	}
+
+	n, err := s.file.Write(line)
+	if err != nil {
+		logger.Errorf("Cannot write audit record to %s: %s", s.path, err)
+		return
+	}
+	s.size += int64(n)
+}
+
+func (s *FileAuditSink) rotate() error {
+	if err := s.file.Close(); err != nil {
+		return err
+	}
+	rotated := fmt.Sprintf("%s.%d", s.path, time.Now().UnixNano())
+	if err := os.Rename(s.path, rotated); err != nil {
+		return err
+	}
+	return s.openFile()
+}
@@ -0,0 +1,84 @@
+/*
+Copyright (C) 2018 Expedia Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pack
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/HotelsDotCom/go-logger/logger"
+)
+
+// syslogAuthFacility and syslogSeverityInfo are the RFC 5424 facility/severity flyte tags audit
+// records with: "security/authorization messages" at the "informational" level.
+const (
+	syslogAuthFacility = 10
+	syslogSeverityInfo = 6
+)
+
+// SyslogAuditSink writes pack lifecycle events as RFC 5424 syslog messages.
+type SyslogAuditSink struct {
+	appName string
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// NewSyslogAuditSink dials a syslog server at addr over network ("udp" or "tcp") and returns a
+// sink that tags every message with appName.
+func NewSyslogAuditSink(network, addr, appName string) (*SyslogAuditSink, error) {
+	conn, err := net.Dial(network, addr)
+	if err != nil {
+		return nil, err
+	}
+	return &SyslogAuditSink{appName: appName, conn: conn}, nil
+}
+
+func (s *SyslogAuditSink) PackCreated(packId, actor, remoteAddr string, at time.Time) {
+	s.send("pack.created", packId, actor, remoteAddr, at)
+}
+
+func (s *SyslogAuditSink) PackUpdated(packId, actor, remoteAddr string, at time.Time) {
+	s.send("pack.updated", packId, actor, remoteAddr, at)
+}
+
+func (s *SyslogAuditSink) PackDeleted(packId, actor, remoteAddr string, at time.Time) {
+	s.send("pack.deleted", packId, actor, remoteAddr, at)
+}
+
+func (s *SyslogAuditSink) send(event, packId, actor, remoteAddr string, at time.Time) {
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "-"
+	}
+
+	pri := syslogAuthFacility*8 + syslogSeverityInfo
+	msg := fmt.Sprintf("%s packId=%q actor=%q remoteAddr=%q", event, packId, actor, remoteAddr)
+	packet := fmt.Sprintf("<%d>1 %s %s %s %d - - %s\n",
+		pri, at.UTC().Format(time.RFC3339), hostname, s.appName, os.Getpid(), msg)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.conn.Write([]byte(packet)); err != nil {
+		logger.Errorf("Cannot write audit record to syslog: %s", err)
+	}
+}
@@ -0,0 +1,150 @@
+/*
+Copyright (C) 2018 Expedia Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pack
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostPack_ShouldAuditPackCreated_OnSuccess(t *testing.T) {
+
+	defer resetPackRepo()
+	defer resetAuditSink()
+	packRepo = mockPackRepo{add: func(pack Pack) error { return nil }}
+	sink := &mockAuditSink{}
+	auditSink = sink
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/packs", strings.NewReader(packRequest))
+	req.RemoteAddr = "10.0.0.1:4321"
+	w := httptest.NewRecorder()
+	PostPack(w, req)
+
+	require.Len(t, sink.created, 1)
+	assert.Equal(t, "Slack", sink.created[0].packId)
+	assert.Equal(t, "10.0.0.1:4321", sink.created[0].remoteAddr)
+}
+
+func TestPostPack_ShouldAuditPackCreated_WhenRepoFailsAfterAuthSucceeded(t *testing.T) {
+
+	defer resetPackRepo()
+	defer resetAuditSink()
+	packRepo = mockPackRepo{add: func(pack Pack) error { return errors.New("something went wrong") }}
+	sink := &mockAuditSink{}
+	auditSink = sink
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/packs", strings.NewReader(packRequest))
+	w := httptest.NewRecorder()
+	PostPack(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Result().StatusCode)
+	require.Len(t, sink.created, 1, "a failed write attempt is still audited")
+}
+
+func TestDeletePack_ShouldAuditPackDeleted_WhenRemoveFails(t *testing.T) {
+
+	defer resetPackRepo()
+	defer resetAuditSink()
+	packRepo = mockPackRepo{
+		get:    func(id string) (*Pack, error) { return &Pack{Id: "Slack", TokenHash: hashToken("s3cr3t")}, nil },
+		remove: func(id string) error { return errors.New("something went wrong") },
+	}
+	sink := &mockAuditSink{}
+	auditSink = sink
+
+	req := httptest.NewRequest(http.MethodDelete, "/v1/packs/Slack?:packId=Slack", nil)
+	req.Header.Set("Authorization", "Bearer s3cr3t")
+	w := httptest.NewRecorder()
+	DeletePack(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Result().StatusCode)
+	require.Len(t, sink.deleted, 1, "a failed delete attempt is still audited")
+	assert.Equal(t, "Slack", sink.deleted[0].packId)
+}
+
+func TestDeletePack_ShouldNotAudit_WhenPackDoesNotExist(t *testing.T) {
+
+	defer resetPackRepo()
+	defer resetAuditSink()
+	packRepo = mockPackRepo{get: func(id string) (*Pack, error) { return nil, PackNotFoundErr }}
+	sink := &mockAuditSink{}
+	auditSink = sink
+
+	req := httptest.NewRequest(http.MethodDelete, "/v1/packs/Slack?:packId=Slack", nil)
+	w := httptest.NewRecorder()
+	DeletePack(w, req)
+
+	assert.Empty(t, sink.deleted)
+}
+
+func TestPutPack_ShouldAuditPackUpdated_WhenReferentialIntegrityRejectsTheUpdate(t *testing.T) {
+
+	defer resetPackRepo()
+	defer resetIntegrityCheckers()
+	defer resetAuditSink()
+
+	current := &Pack{Id: "Slack", Name: "Slack", TokenHash: hashToken("s3cr3t"), Version: 1,
+		Commands: []Command{{Name: "SendMessage"}, {Name: "DeleteMessage"}}}
+	packRepo = mockPackRepo{get: func(id string) (*Pack, error) { return current, nil }}
+	pendingActions = fakePendingActionChecker{hasPending: map[string]bool{"DeleteMessage": true}}
+	sink := &mockAuditSink{}
+	auditSink = sink
+
+	req := httptest.NewRequest(http.MethodPut, "/v1/packs/Slack?:packId=Slack", strings.NewReader(putPackRequest))
+	req.Header.Set("Authorization", "Bearer s3cr3t")
+	w := httptest.NewRecorder()
+	PutPack(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Result().StatusCode)
+	require.Len(t, sink.updated, 1, "a rejected update is still a recorded attempt to change the pack")
+}
+
+func resetAuditSink() {
+	auditSink = noopAuditSink{}
+}
+
+type auditCall struct {
+	packId     string
+	actor      string
+	remoteAddr string
+	at         time.Time
+}
+
+type mockAuditSink struct {
+	created []auditCall
+	updated []auditCall
+	deleted []auditCall
+}
+
+func (m *mockAuditSink) PackCreated(packId, actor, remoteAddr string, at time.Time) {
+	m.created = append(m.created, auditCall{packId, actor, remoteAddr, at})
+}
+
+func (m *mockAuditSink) PackUpdated(packId, actor, remoteAddr string, at time.Time) {
+	m.updated = append(m.updated, auditCall{packId, actor, remoteAddr, at})
+}
+
+func (m *mockAuditSink) PackDeleted(packId, actor, remoteAddr string, at time.Time) {
+	m.deleted = append(m.deleted, auditCall{packId, actor, remoteAddr, at})
+}
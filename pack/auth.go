@@ -0,0 +1,252 @@
+/*
+Copyright (C) 2018 Expedia Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package pack's auth.go implements pack-scoped authentication: per-pack API tokens minted on
+// registration, and a set of pluggable backends (Basic, OIDC) that can additionally be allowed
+// per pack via Pack.AuthPolicy.
+package pack
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/HotelsDotCom/go-logger/logger"
+)
+
+// AuthPolicy lets an operator restrict how a pack may authenticate, beyond its own minted token.
+// A nil AuthPolicy adds no restriction: the pack's own token, or a verified OIDC bearer token
+// from any configured issuer whose subject is the pack id, is accepted.
+type AuthPolicy struct {
+	// AllowedIssuers is the set of OIDC issuers this pack will accept bearer tokens from.
+	AllowedIssuers []string `json:"allowedIssuers,omitempty" bson:"allowedIssuers,omitempty"`
+	// RequiredRoles, if set, must all be present in a bearer token's "roles" claim.
+	RequiredRoles []string `json:"requiredRoles,omitempty" bson:"requiredRoles,omitempty"`
+}
+
+// authBackend validates the credentials on a request for the named pack, returning an error if
+// the request is not authenticated as that pack.
+type authBackend interface {
+	authenticate(r *http.Request, pack *Pack) error
+}
+
+// authBackends are tried in order; the first to accept the request wins. PostPack has no
+// associated pack yet, so it is authenticated separately (see authenticateAdmin). basicAuthBackend
+// only participates here (p != nil) when ConfigurePackBasicAuth has opted it in - otherwise the
+// shared admin credential would be a skeleton key for every pack's AuthPolicy.
+var authBackends = []authBackend{
+	packTokenBackend{},
+	basicAuthBackend{},
+	oidcAuthBackend{},
+}
+
+var errUnauthenticated = errors.New("unauthenticated")
+
+// Authenticate wraps a pack-scoped handler (PostPack excepted) so that the caller must present
+// either the pack's own API token or a bearer token accepted by the pack's AuthPolicy, or, only
+// when ConfigurePackBasicAuth has opted it in, the shared Basic auth credential. packId is
+// extracted the same way the wrapped handler extracts it, via the ":packId" pat parameter.
+//
+// This package only owns PutPack/PatchPack, DeletePack and the heartbeat endpoint, so those are
+// the only handlers wrapped here. Action and event endpoints for a pack (taking an action,
+// recording an event) are not implemented in this tree - there is no action/event HTTP package
+// to wire yet. When one exists, its router should wrap each pack-scoped handler the same way:
+// `action.TakeAction = pack.Authenticate(takeAction)`, relying on the same ":packId" pat
+// parameter this middleware already extracts.
+func Authenticate(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+
+		packId := r.URL.Query().Get(":packId")
+		p, err := packRepo.Get(packId)
+		if err == PackNotFoundErr {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			logger.Errorf("Cannot find packId=%s: %s", packId, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		if err := authenticatePack(r, p); err != nil {
+			logger.Errorf("Cannot authenticate packId=%s: %s", packId, err)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+func authenticatePack(r *http.Request, p *Pack) error {
+	for _, backend := range authBackends {
+		if err := backend.authenticate(r, p); err == nil {
+			return nil
+		}
+	}
+	return errUnauthenticated
+}
+
+// AuthenticateRegistration wraps PostPack. Pack registration predates any pack-specific token, so
+// it is checked against the server-wide admin backends (Basic, OIDC with no subject binding)
+// instead of authenticatePack. If no admin backend has been configured (the flyte default),
+// registration stays open, preserving today's anonymous-registration behaviour.
+func AuthenticateRegistration(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+
+		if !adminAuthConfigured() {
+			next(w, r)
+			return
+		}
+
+		actor, err := authenticateAdmin(r)
+		if err != nil {
+			logger.Errorf("Cannot authenticate registration request: %s", err)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		next(w, withActor(r, actor))
+	}
+}
+
+// adminOidcIssuers is the set of issuers trusted to authenticate registration (admin) requests.
+// This is deliberately separate from the issuers a pack's own AuthPolicy.AllowedIssuers accepts:
+// an issuer configured so one pack can authenticate itself must not thereby let any token holder
+// from that issuer register brand-new packs. An operator must opt an issuer into admin use
+// explicitly via ConfigureAdminOidcIssuer, in addition to registering it with ConfigureOidcIssuer
+// so its JWKS endpoint is known.
+var adminOidcIssuers = map[string]bool{}
+
+// adminOidcRequiredRoles, if set, must all be present in an admin bearer token's "roles" claim.
+var adminOidcRequiredRoles []string
+
+// ConfigureAdminOidcIssuer trusts issuer to authenticate pack registration requests
+// (AuthenticateRegistration), on top of or instead of ConfigureBasicAuth.
+func ConfigureAdminOidcIssuer(issuer string) {
+	adminOidcIssuers[issuer] = true
+}
+
+// ConfigureAdminOidcRoles requires every role in roles to be present on an admin bearer token,
+// in addition to it coming from an issuer configured via ConfigureAdminOidcIssuer.
+func ConfigureAdminOidcRoles(roles []string) {
+	adminOidcRequiredRoles = roles
+}
+
+func adminAuthConfigured() bool {
+	return basicAuthCredentials.username != "" || len(adminOidcIssuers) > 0
+}
+
+// authenticateAdmin returns the identity of whoever authenticated, for the audit trail: the Basic
+// auth username, or "oidc:<subject>" (falling back to "oidc:<issuer>" for a token with no
+// subject) for the OIDC path. There is no pack yet at registration time to fall back to.
+func authenticateAdmin(r *http.Request) (string, error) {
+	if (basicAuthBackend{}).authenticate(r, nil) == nil {
+		username, _, _ := r.BasicAuth()
+		return username, nil
+	}
+
+	claims, err := parseAndVerify(r.Context(), bearerToken(r))
+	if err != nil || !adminOidcIssuers[claims.Issuer] {
+		return "", errUnauthenticated
+	}
+	for _, role := range adminOidcRequiredRoles {
+		if !contains(claims.Roles, role) {
+			return "", errUnauthenticated
+		}
+	}
+
+	if claims.Subject != "" {
+		return "oidc:" + claims.Subject, nil
+	}
+	return "oidc:" + claims.Issuer, nil
+}
+
+// --- actor context ---
+//
+// The auth middleware knows exactly who authenticated a request; actorFor (audit.go) otherwise
+// has to guess from the request alone, which is wrong for a registration authenticated by OIDC
+// (there is no pack yet for it to fall back to). withActor/actorFromContext let a middleware
+// record the actor it determined, for actorFor to prefer over its own guesswork.
+
+type contextKey string
+
+const actorContextKey contextKey = "pack.actor"
+
+func withActor(r *http.Request, actor string) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), actorContextKey, actor))
+}
+
+func actorFromContext(r *http.Request) (string, bool) {
+	actor, ok := r.Context().Value(actorContextKey).(string)
+	return actor, ok
+}
+
+// --- per-pack API tokens ---
+
+// issuePackToken mints a new plaintext token for p and sets p.TokenHash to its SHA-256 hash. The
+// plaintext is returned to the caller exactly once; flyte never stores or re-displays it.
+func issuePackToken(p *Pack) (string, error) {
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(raw)
+	p.TokenHash = hashToken(token)
+	return token, nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// packTokenBackend authenticates a pack against the token minted for it by PostPack, passed as
+// "Bearer <token>" or "Token <token>" in the Authorization header.
+type packTokenBackend struct{}
+
+func (packTokenBackend) authenticate(r *http.Request, p *Pack) error {
+
+	if p.TokenHash == "" {
+		return errUnauthenticated
+	}
+
+	token := bearerToken(r)
+	if token == "" {
+		return errUnauthenticated
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashToken(token)), []byte(p.TokenHash)) != 1 {
+		return errUnauthenticated
+	}
+	return nil
+}
+
+func bearerToken(r *http.Request) string {
+	auth := r.Header.Get("Authorization")
+	for _, prefix := range []string{"Bearer ", "Token "} {
+		if strings.HasPrefix(auth, prefix) {
+			return strings.TrimPrefix(auth, prefix)
+		}
+	}
+	return ""
+}
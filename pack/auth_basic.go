@@ -0,0 +1,81 @@
+/*
+Copyright (C) 2018 Expedia Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pack
+
+import (
+	"crypto/subtle"
+	"net/http"
+)
+
+// basicAuthCredentials is populated at startup from flyte's server config; it is the one set of
+// HTTP Basic credentials an operator can configure for pack registration/administration. It
+// authenticates pack-scoped requests only when ConfigurePackBasicAuth opts it in, and then for
+// every pack alike: a pack's AuthPolicy does not restrict it.
+var basicAuthCredentials struct {
+	username string
+	password string
+}
+
+// ConfigureBasicAuth sets the shared Basic auth credentials checked by basicAuthBackend.
+// Called once at server startup; an empty username disables the backend.
+func ConfigureBasicAuth(username, password string) {
+	basicAuthCredentials.username = username
+	basicAuthCredentials.password = password
+}
+
+// packBasicAuthEnabled gates whether the shared ConfigureBasicAuth credential authenticates
+// pack-scoped requests (PutPack/DeletePack/heartbeat, via authenticatePack) in addition to
+// registration. Off by default: an operator who configures Basic auth only to gate registration
+// (AuthenticateRegistration) should not thereby hand every pack's delete/update/heartbeat a
+// skeleton key that bypasses that pack's own AuthPolicy.AllowedIssuers/RequiredRoles.
+var packBasicAuthEnabled = false
+
+// ConfigurePackBasicAuth opts the shared Basic auth credential into also authenticating
+// pack-scoped requests. Most operators should leave this off and rely on per-pack tokens or
+// AuthPolicy instead.
+func ConfigurePackBasicAuth(enabled bool) {
+	packBasicAuthEnabled = enabled
+}
+
+// basicAuthBackend authenticates a caller using a single shared HTTP Basic username/password,
+// configured server-wide via ConfigureBasicAuth. For a pack-scoped request (p != nil) it only
+// applies when separately opted in via ConfigurePackBasicAuth; for registration/admin use
+// (p == nil, see authenticateAdmin) configuring the credential is enough on its own.
+type basicAuthBackend struct{}
+
+func (basicAuthBackend) authenticate(r *http.Request, p *Pack) error {
+
+	if p != nil && !packBasicAuthEnabled {
+		return errUnauthenticated
+	}
+
+	if basicAuthCredentials.username == "" {
+		return errUnauthenticated
+	}
+
+	username, password, ok := r.BasicAuth()
+	if !ok {
+		return errUnauthenticated
+	}
+
+	usernameMatch := subtle.ConstantTimeCompare([]byte(username), []byte(basicAuthCredentials.username)) == 1
+	passwordMatch := subtle.ConstantTimeCompare([]byte(password), []byte(basicAuthCredentials.password)) == 1
+	if !usernameMatch || !passwordMatch {
+		return errUnauthenticated
+	}
+	return nil
+}
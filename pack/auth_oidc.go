@@ -0,0 +1,188 @@
+/*
+Copyright (C) 2018 Expedia Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pack
+
+import (
+	"context"
+	"crypto/rsa"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// oidcExpectedAudience is the "aud" claim flyte requires of every OIDC bearer token, regardless
+// of the pack it is presented for.
+var oidcExpectedAudience = "flyte"
+
+var errUnknownIssuer = errors.New("unknown or unconfigured issuer")
+
+// oidcAuthBackend validates an OIDC bearer token against its issuer's JWKS, then checks that the
+// token's subject matches the pack id and, if the pack has an AuthPolicy, that the issuer and
+// roles are allowed.
+type oidcAuthBackend struct{}
+
+func (oidcAuthBackend) authenticate(r *http.Request, p *Pack) error {
+
+	token := bearerToken(r)
+	if token == "" {
+		return errUnauthenticated
+	}
+
+	claims, err := parseAndVerify(r.Context(), token)
+	if err != nil {
+		return err
+	}
+
+	if claims.Subject != p.Id {
+		return errUnauthenticated
+	}
+	return checkAuthPolicy(p.AuthPolicy, claims)
+}
+
+type oidcClaims struct {
+	jwt.StandardClaims
+	Roles []string `json:"roles"`
+}
+
+func parseAndVerify(ctx context.Context, rawToken string) (*oidcClaims, error) {
+
+	claims := &oidcClaims{}
+	_, err := jwt.ParseWithClaims(rawToken, claims, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodRSA); !ok {
+			return nil, errors.New("unexpected signing method")
+		}
+		kid, _ := t.Header["kid"].(string)
+		return jwksCache.publicKey(ctx, claims.Issuer, kid)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := claims.Valid(); err != nil {
+		return nil, err
+	}
+	if claims.Audience != oidcExpectedAudience {
+		return nil, errors.New("unexpected audience")
+	}
+	return claims, nil
+}
+
+func checkAuthPolicy(policy *AuthPolicy, claims *oidcClaims) error {
+	if policy == nil {
+		return nil
+	}
+
+	if len(policy.AllowedIssuers) > 0 && !contains(policy.AllowedIssuers, claims.Issuer) {
+		return errUnknownIssuer
+	}
+
+	for _, role := range policy.RequiredRoles {
+		if !contains(claims.Roles, role) {
+			return errors.New("missing required role: " + role)
+		}
+	}
+	return nil
+}
+
+func contains(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+// --- JWKS ---
+
+// jwksCache resolves an issuer's signing keys, fetching and caching its JWKS document.
+var jwksCache = newJwksResolver(15 * time.Minute)
+
+type jwksResolver struct {
+	ttl   time.Duration
+	mu    sync.Mutex
+	byIss map[string]jwksEntry
+}
+
+type jwksEntry struct {
+	fetchedAt time.Time
+	keys      map[string]*rsa.PublicKey
+}
+
+func newJwksResolver(ttl time.Duration) *jwksResolver {
+	return &jwksResolver{ttl: ttl, byIss: map[string]jwksEntry{}}
+}
+
+// issuerJwksUri maps an issuer to its JWKS endpoint. Populated at startup from server config,
+// keyed by issuer so untrusted "iss" claims are never used to construct a fetch URL.
+var issuerJwksUri = map[string]string{}
+
+// ConfigureOidcIssuer registers an issuer flyte will accept bearer tokens from, and the JWKS
+// endpoint used to fetch its current signing keys.
+func ConfigureOidcIssuer(issuer, jwksUri string) {
+	issuerJwksUri[issuer] = jwksUri
+}
+
+func (c *jwksResolver) publicKey(ctx context.Context, issuer, kid string) (*rsa.PublicKey, error) {
+
+	c.mu.Lock()
+	entry, ok := c.byIss[issuer]
+	fresh := ok && time.Since(entry.fetchedAt) < c.ttl
+	c.mu.Unlock()
+
+	if !fresh {
+		refreshed, err := c.fetch(ctx, issuer)
+		if err != nil {
+			if ok {
+				// Fall back to the stale keys rather than fail closed on a transient fetch error.
+				c.mu.Lock()
+				entry = c.byIss[issuer]
+				c.mu.Unlock()
+			} else {
+				return nil, err
+			}
+		} else {
+			entry = refreshed
+			c.mu.Lock()
+			c.byIss[issuer] = entry
+			c.mu.Unlock()
+		}
+	}
+
+	key, ok := entry.keys[kid]
+	if !ok {
+		return nil, errors.New("no matching key for kid=" + kid)
+	}
+	return key, nil
+}
+
+func (c *jwksResolver) fetch(ctx context.Context, issuer string) (jwksEntry, error) {
+
+	uri, ok := issuerJwksUri[issuer]
+	if !ok {
+		return jwksEntry{}, errUnknownIssuer
+	}
+
+	keys, err := fetchJwks(ctx, uri)
+	if err != nil {
+		return jwksEntry{}, err
+	}
+	return jwksEntry{fetchedAt: time.Now(), keys: keys}, nil
+}
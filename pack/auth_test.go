@@ -0,0 +1,303 @@
+/*
+Copyright (C) 2018 Expedia Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pack
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthenticate_ShouldAllowRequest_WithValidPackToken(t *testing.T) {
+
+	defer resetPackRepo()
+	packRepo = mockPackRepo{
+		get: func(id string) (*Pack, error) {
+			return &Pack{Id: "Slack", TokenHash: hashToken("s3cr3t")}, nil
+		},
+	}
+
+	called := false
+	handler := Authenticate(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	})
+
+	req := httptest.NewRequest(http.MethodDelete, "/v1/packs/Slack?:packId=Slack", nil)
+	req.Header.Set("Authorization", "Bearer s3cr3t")
+	handler(httptest.NewRecorder(), req)
+
+	assert.True(t, called)
+}
+
+func TestAuthenticate_ShouldReject_WithWrongPackToken(t *testing.T) {
+
+	defer resetPackRepo()
+	packRepo = mockPackRepo{
+		get: func(id string) (*Pack, error) {
+			return &Pack{Id: "Slack", TokenHash: hashToken("s3cr3t")}, nil
+		},
+	}
+
+	called := false
+	handler := Authenticate(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	})
+
+	req := httptest.NewRequest(http.MethodDelete, "/v1/packs/Slack?:packId=Slack", nil)
+	req.Header.Set("Authorization", "Bearer wrong-token")
+	w := httptest.NewRecorder()
+	handler(w, req)
+
+	assert.False(t, called)
+	assert.Equal(t, http.StatusUnauthorized, w.Result().StatusCode)
+}
+
+func TestAuthenticate_ShouldReject_WithSharedBasicAuthCredentials_WhenNotOptedInForPacks(t *testing.T) {
+
+	defer resetPackRepo()
+	defer ConfigureBasicAuth("", "")
+	defer ConfigurePackBasicAuth(false)
+	packRepo = mockPackRepo{
+		get: func(id string) (*Pack, error) {
+			return &Pack{Id: "Slack", TokenHash: hashToken("s3cr3t")}, nil
+		},
+	}
+	ConfigureBasicAuth("admin", "secret")
+
+	called := false
+	handler := Authenticate(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	})
+
+	req := httptest.NewRequest(http.MethodDelete, "/v1/packs/Slack?:packId=Slack", nil)
+	req.SetBasicAuth("admin", "secret")
+	w := httptest.NewRecorder()
+	handler(w, req)
+
+	assert.False(t, called, "the shared admin credential must not be a skeleton key for every pack's own AuthPolicy")
+	assert.Equal(t, http.StatusUnauthorized, w.Result().StatusCode)
+}
+
+func TestAuthenticate_ShouldAllowRequest_WithSharedBasicAuthCredentials_WhenOptedInForPacks(t *testing.T) {
+
+	defer resetPackRepo()
+	defer ConfigureBasicAuth("", "")
+	defer ConfigurePackBasicAuth(false)
+	packRepo = mockPackRepo{
+		get: func(id string) (*Pack, error) {
+			return &Pack{Id: "Slack", TokenHash: hashToken("s3cr3t")}, nil
+		},
+	}
+	ConfigureBasicAuth("admin", "secret")
+	ConfigurePackBasicAuth(true)
+
+	called := false
+	handler := Authenticate(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	})
+
+	req := httptest.NewRequest(http.MethodDelete, "/v1/packs/Slack?:packId=Slack", nil)
+	req.SetBasicAuth("admin", "secret")
+	w := httptest.NewRecorder()
+	handler(w, req)
+
+	assert.True(t, called)
+}
+
+func TestBasicAuthBackend_ShouldAuthenticate_WithConfiguredCredentials(t *testing.T) {
+
+	defer ConfigureBasicAuth("", "")
+	ConfigureBasicAuth("admin", "secret")
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/packs", nil)
+	req.SetBasicAuth("admin", "secret")
+
+	err := (basicAuthBackend{}).authenticate(req, nil)
+	assert.NoError(t, err)
+}
+
+func TestBasicAuthBackend_ShouldReject_WithWrongCredentials(t *testing.T) {
+
+	defer ConfigureBasicAuth("", "")
+	ConfigureBasicAuth("admin", "secret")
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/packs", nil)
+	req.SetBasicAuth("admin", "wrong")
+
+	err := (basicAuthBackend{}).authenticate(req, nil)
+	assert.Error(t, err)
+}
+
+func TestBasicAuthBackend_ShouldReject_WhenNotConfigured(t *testing.T) {
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/packs", nil)
+	req.SetBasicAuth("admin", "secret")
+
+	err := (basicAuthBackend{}).authenticate(req, nil)
+	assert.Error(t, err)
+}
+
+func TestCheckAuthPolicy_ShouldRejectUnallowedIssuer(t *testing.T) {
+
+	policy := &AuthPolicy{AllowedIssuers: []string{"https://issuer.example.com"}}
+	claims := &oidcClaims{}
+	claims.Issuer = "https://other-issuer.example.com"
+
+	err := checkAuthPolicy(policy, claims)
+	assert.Error(t, err)
+}
+
+func TestCheckAuthPolicy_ShouldRejectMissingRole(t *testing.T) {
+
+	policy := &AuthPolicy{RequiredRoles: []string{"pack-operator"}}
+	claims := &oidcClaims{Roles: []string{"viewer"}}
+
+	err := checkAuthPolicy(policy, claims)
+	assert.Error(t, err)
+}
+
+func TestCheckAuthPolicy_ShouldAllow_WhenIssuerAndRolesMatch(t *testing.T) {
+
+	policy := &AuthPolicy{AllowedIssuers: []string{"https://issuer.example.com"}, RequiredRoles: []string{"pack-operator"}}
+	claims := &oidcClaims{Roles: []string{"pack-operator", "viewer"}}
+	claims.Issuer = "https://issuer.example.com"
+
+	err := checkAuthPolicy(policy, claims)
+	assert.NoError(t, err)
+}
+
+func TestCheckAuthPolicy_ShouldAllow_WhenPolicyIsNil(t *testing.T) {
+	assert.NoError(t, checkAuthPolicy(nil, &oidcClaims{}))
+}
+
+// --- OIDC / JWKS round trip, against a real RSA-signed JWT and a fake JWKS server ---
+
+func TestParseAndVerify_ShouldRoundTripViaRealJwksServer(t *testing.T) {
+
+	defer resetJwksCache()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJwksResponse(w, "test-kid", &key.PublicKey)
+	}))
+	defer server.Close()
+
+	issuer := "https://issuer.example.com"
+	ConfigureOidcIssuer(issuer, server.URL)
+
+	token := signTestOidcToken(t, key, "test-kid", issuer, "Slack", []string{"pack-operator"})
+
+	claims, err := parseAndVerify(context.Background(), token)
+	require.NoError(t, err)
+	assert.Equal(t, "Slack", claims.Subject)
+	assert.Equal(t, issuer, claims.Issuer)
+	assert.Equal(t, []string{"pack-operator"}, claims.Roles)
+}
+
+func TestParseAndVerify_ShouldRejectTokenSignedByUnknownKey(t *testing.T) {
+
+	defer resetJwksCache()
+
+	servedKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	signingKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJwksResponse(w, "test-kid", &servedKey.PublicKey)
+	}))
+	defer server.Close()
+
+	issuer := "https://issuer.example.com"
+	ConfigureOidcIssuer(issuer, server.URL)
+
+	token := signTestOidcToken(t, signingKey, "test-kid", issuer, "Slack", nil)
+
+	_, err = parseAndVerify(context.Background(), token)
+	assert.Error(t, err)
+}
+
+func TestParseAndVerify_ShouldRejectExpiredToken(t *testing.T) {
+
+	defer resetJwksCache()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJwksResponse(w, "test-kid", &key.PublicKey)
+	}))
+	defer server.Close()
+
+	issuer := "https://issuer.example.com"
+	ConfigureOidcIssuer(issuer, server.URL)
+
+	claims := &oidcClaims{}
+	claims.Issuer = issuer
+	claims.Subject = "Slack"
+	claims.Audience = oidcExpectedAudience
+	claims.ExpiresAt = time.Now().Add(-time.Hour).Unix()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = "test-kid"
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+
+	_, err = parseAndVerify(context.Background(), signed)
+	assert.Error(t, err)
+}
+
+func signTestOidcToken(t *testing.T, key *rsa.PrivateKey, kid, issuer, subject string, roles []string) string {
+	t.Helper()
+
+	claims := &oidcClaims{Roles: roles}
+	claims.Issuer = issuer
+	claims.Subject = subject
+	claims.Audience = oidcExpectedAudience
+	claims.ExpiresAt = time.Now().Add(time.Hour).Unix()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func writeJwksResponse(w http.ResponseWriter, kid string, key *rsa.PublicKey) {
+	n := base64.RawURLEncoding.EncodeToString(key.N.Bytes())
+	e := base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes())
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprintf(w, `{"keys":[{"kid":%q,"kty":"RSA","n":%q,"e":%q}]}`, kid, n, e)
+}
+
+func resetJwksCache() {
+	jwksCache = newJwksResolver(15 * time.Minute)
+	issuerJwksUri = map[string]string{}
+}
@@ -0,0 +1,127 @@
+/*
+Copyright (C) 2018 Expedia Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pack
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/HotelsDotCom/flyte/httputil"
+	"github.com/HotelsDotCom/go-logger/logger"
+)
+
+// PostPack registers a new pack and mints its API token (see auth.go). The token is returned
+// once in the response body; flyte does not retain it in plaintext. Registration itself is
+// gated by AuthenticateRegistration, not the per-pack token it mints.
+var PostPack = AuthenticateRegistration(postPack)
+
+func postPack(w http.ResponseWriter, r *http.Request) {
+
+	p := Pack{}
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		logger.Errorf("Cannot convert request to pack: %s", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	p.generateId()
+
+	token, err := issuePackToken(&p)
+	if err != nil {
+		logger.Errorf("Cannot issue token for packName=%s: %s", p.Name, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	err = packRepo.Add(p)
+	auditSink.PackCreated(p.Id, actorFor(r, p.Id), r.RemoteAddr, time.Now())
+	if err != nil {
+		logger.Errorf("Cannot save packName=%s, packLabels=%v: %s", p.Name, p.Labels, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("%s/v1/packs/%s", httputil.ProtocolAndHost(r), p.Id))
+	writeJson(w, http.StatusCreated, postPackResponse{Token: token})
+}
+
+type postPackResponse struct {
+	Token string `json:"token"`
+}
+
+// GetPack returns a single pack by id.
+func GetPack(w http.ResponseWriter, r *http.Request) {
+
+	id := r.URL.Query().Get(":packId")
+	p, err := packRepo.Get(id)
+	if err == PackNotFoundErr {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		logger.Errorf("Cannot find packId=%s: %s", id, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	p.addLinks(r)
+	writeJson(w, http.StatusOK, p)
+}
+
+// GetPacks returns all registered packs.
+func GetPacks(w http.ResponseWriter, r *http.Request) {
+
+	packs, err := packRepo.FindAll()
+	if err != nil {
+		logger.Errorf("Cannot find packs: %s", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	writeJson(w, http.StatusOK, newPacksResponse(r, packs))
+}
+
+// DeletePack removes a pack by id. The caller must authenticate as the pack (its token, or an
+// OIDC token whose subject matches the pack id), or with the shared Basic auth credential when
+// ConfigurePackBasicAuth has opted it in; see Authenticate in auth.go.
+var DeletePack = Authenticate(deletePack)
+
+func deletePack(w http.ResponseWriter, r *http.Request) {
+
+	id := r.URL.Query().Get(":packId")
+	err := packRepo.Remove(id)
+	if err == PackNotFoundErr {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	auditSink.PackDeleted(id, actorFor(r, id), r.RemoteAddr, time.Now())
+	if err != nil {
+		logger.Errorf("Cannot delete packId=%s: %s", id, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeJson(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set(httputil.HeaderContentType, httputil.ContentTypeJson)
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
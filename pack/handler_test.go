@@ -29,6 +29,7 @@ import (
 	"github.com/HotelsDotCom/go-logger/loggertest"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestPostPack_ShouldCreatePackForValidRequest(t *testing.T) {
@@ -54,13 +55,53 @@ func TestPostPack_ShouldCreatePackForValidRequest(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, "http://example.com/v1/packs/Slack", location.String())
 
+	body := postPackResponse{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.NotEmpty(t, body.Token)
+
 	var expectedPack Pack
 	err = json.Unmarshal([]byte(packRequest), &expectedPack)
 	require.NoError(t, err)
 	expectedPack.generateId()
+	expectedPack.TokenHash = hashToken(body.Token)
 	assert.Equal(t, expectedPack, actualPack)
 }
 
+func TestPostPack_ShouldReturn401_WhenRegistrationAuthConfiguredAndNoCredentialsGiven(t *testing.T) {
+
+	defer resetPackRepo()
+	defer ConfigureBasicAuth("", "")
+	ConfigureBasicAuth("admin", "secret")
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/packs", strings.NewReader(packRequest))
+	w := httptest.NewRecorder()
+	PostPack(w, req)
+
+	resp := w.Result()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
+
+func TestPostPack_ShouldCreatePack_WhenRegistrationAuthConfiguredAndValidBasicCredentialsGiven(t *testing.T) {
+
+	defer resetPackRepo()
+	defer ConfigureBasicAuth("", "")
+	ConfigureBasicAuth("admin", "secret")
+	packRepo = mockPackRepo{
+		add: func(pack Pack) error {
+			return nil
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/packs", strings.NewReader(packRequest))
+	req.SetBasicAuth("admin", "secret")
+	httputil.SetProtocolAndHostIn(req)
+	w := httptest.NewRecorder()
+	PostPack(w, req)
+
+	resp := w.Result()
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+}
+
 func TestPostPack_ShouldReturn400ForInvalidRequest(t *testing.T) {
 
 	defer loggertest.Reset()
@@ -250,12 +291,16 @@ func TestDeletePack_ShouldDeleteExistingPack(t *testing.T) {
 
 	defer resetPackRepo()
 	packRepo = mockPackRepo{
+		get: func(id string) (*Pack, error) {
+			return &Pack{Id: "Slack", TokenHash: hashToken("s3cr3t")}, nil
+		},
 		remove: func(id string) error {
 			return nil
 		},
 	}
 
-	req := httptest.NewRequest(http.MethodDelete, "/v1/packs/Slack", nil)
+	req := httptest.NewRequest(http.MethodDelete, "/v1/packs/Slack?:packId=Slack", nil)
+	req.Header.Set("Authorization", "Bearer s3cr3t")
 	w := httptest.NewRecorder()
 	DeletePack(w, req)
 
@@ -267,16 +312,33 @@ func TestDeletePack_ShouldDeleteExistingPack(t *testing.T) {
 	assert.Empty(t, string(body))
 }
 
+func TestDeletePack_Should401ForMissingOrInvalidToken(t *testing.T) {
+
+	defer resetPackRepo()
+	packRepo = mockPackRepo{
+		get: func(id string) (*Pack, error) {
+			return &Pack{Id: "Slack", TokenHash: hashToken("s3cr3t")}, nil
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodDelete, "/v1/packs/Slack?:packId=Slack", nil)
+	w := httptest.NewRecorder()
+	DeletePack(w, req)
+
+	resp := w.Result()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
+
 func TestDeletePack_Should404ForNonExistingPack(t *testing.T) {
 
 	defer resetPackRepo()
 	packRepo = mockPackRepo{
-		remove: func(id string) error {
-			return PackNotFoundErr
+		get: func(id string) (*Pack, error) {
+			return nil, PackNotFoundErr
 		},
 	}
 
-	req := httptest.NewRequest(http.MethodDelete, "/v1/packs/Slack", nil)
+	req := httptest.NewRequest(http.MethodDelete, "/v1/packs/Slack?:packId=Slack", nil)
 	w := httptest.NewRecorder()
 	DeletePack(w, req)
 
@@ -291,12 +353,16 @@ func TestDeletePack_Should500_WhenRepoFails(t *testing.T) {
 
 	defer resetPackRepo()
 	packRepo = mockPackRepo{
+		get: func(id string) (*Pack, error) {
+			return &Pack{Id: "Slack", TokenHash: hashToken("s3cr3t")}, nil
+		},
 		remove: func(id string) error {
 			return errors.New("something went wrong")
 		},
 	}
 
-	req := httptest.NewRequest(http.MethodDelete, "/v1/packs/Slack", nil)
+	req := httptest.NewRequest(http.MethodDelete, "/v1/packs/Slack?:packId=Slack", nil)
+	req.Header.Set("Authorization", "Bearer s3cr3t")
 	w := httptest.NewRecorder()
 	DeletePack(w, req)
 
@@ -305,7 +371,7 @@ func TestDeletePack_Should500_WhenRepoFails(t *testing.T) {
 
 	logMessages := loggertest.GetLogMessages()
 	require.Len(t, logMessages, 1)
-	assert.Equal(t, "Cannot delete packId=: something went wrong", logMessages[0].Message)
+	assert.Equal(t, "Cannot delete packId=Slack: something went wrong", logMessages[0].Message)
 }
 
 // --- requests/responses ---
@@ -400,6 +466,10 @@ var slackPackResponse = strings.Replace(strings.Replace(`
         {
             "href": "http://example.com/v1/packs/Slack/events",
             "rel": "http://example.com/swagger#/event"
+        },
+        {
+            "href": "http://example.com/v1/packs/Slack",
+            "rel": "status"
         }
     ]
 }
@@ -432,6 +502,10 @@ var slackAndHipchatPacksResponse = strings.Replace(strings.Replace(`
                 {
                     "href": "http://example.com/v1/packs/Slack",
                     "rel": "self"
+                },
+                {
+                    "href": "http://example.com/v1/packs/Slack",
+                    "rel": "status"
                 }
             ]
         },
@@ -442,6 +516,10 @@ var slackAndHipchatPacksResponse = strings.Replace(strings.Replace(`
                 {
                     "href": "http://example.com/v1/packs/HipChat",
                     "rel": "self"
+                },
+                {
+                    "href": "http://example.com/v1/packs/HipChat",
+                    "rel": "status"
                 }
             ]
         }
@@ -472,16 +550,23 @@ var emptyPacksResponse = strings.Replace(strings.Replace(`
 // --- mocks & helpers ---
 
 type mockPackRepo struct {
-	add     func(pack Pack) error
-	remove  func(id string) error
-	get     func(id string) (*Pack, error)
-	findAll func() ([]Pack, error)
+	add          func(pack Pack) error
+	update       func(pack Pack) error
+	remove       func(id string) error
+	get          func(id string) (*Pack, error)
+	findAll      func() ([]Pack, error)
+	heartbeat    func(id string, seenAt time.Time) error
+	updateStatus func(id string, status Status) error
 }
 
 func (r mockPackRepo) Add(pack Pack) error {
 	return r.add(pack)
 }
 
+func (r mockPackRepo) Update(pack Pack) error {
+	return r.update(pack)
+}
+
 func (r mockPackRepo) Remove(id string) error {
 	return r.remove(id)
 }
@@ -494,6 +579,14 @@ func (r mockPackRepo) FindAll() ([]Pack, error) {
 	return r.findAll()
 }
 
+func (r mockPackRepo) Heartbeat(id string, seenAt time.Time) error {
+	return r.heartbeat(id, seenAt)
+}
+
+func (r mockPackRepo) UpdateStatus(id string, status Status) error {
+	return r.updateStatus(id, status)
+}
+
 func resetPackRepo() {
 	packRepo = packMgoRepo{}
 }
\ No newline at end of file
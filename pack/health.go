@@ -0,0 +1,55 @@
+/*
+Copyright (C) 2018 Expedia Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pack
+
+import (
+	"net/http"
+
+	"github.com/HotelsDotCom/go-logger/logger"
+)
+
+type healthResponse struct {
+	Alive int `json:"alive"`
+	Stale int `json:"stale"`
+	Dead  int `json:"dead"`
+}
+
+// GetHealth reports how many registered packs are in each liveness status, for ops tooling to
+// scrape; it is mounted at the top level (/v1/health), not under /v1/packs.
+func GetHealth(w http.ResponseWriter, r *http.Request) {
+
+	packs, err := packRepo.FindAll()
+	if err != nil {
+		logger.Errorf("Cannot find packs for health: %s", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	counts := healthResponse{}
+	for _, p := range packs {
+		switch p.Status {
+		case StatusStale:
+			counts.Stale++
+		case StatusDead:
+			counts.Dead++
+		default:
+			counts.Alive++
+		}
+	}
+
+	writeJson(w, http.StatusOK, counts)
+}
@@ -0,0 +1,54 @@
+/*
+Copyright (C) 2018 Expedia Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pack
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetHealth_ShouldReturnCountsPerStatus(t *testing.T) {
+
+	defer resetPackRepo()
+	packRepo = mockPackRepo{
+		findAll: func() ([]Pack, error) {
+			return []Pack{
+				{Id: "a", Status: StatusAlive},
+				{Id: "b", Status: ""},
+				{Id: "c", Status: StatusStale},
+				{Id: "d", Status: StatusDead},
+				{Id: "e", Status: StatusDead},
+			}, nil
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/health", nil)
+	w := httptest.NewRecorder()
+	GetHealth(w, req)
+
+	resp := w.Result()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	body := healthResponse{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, healthResponse{Alive: 2, Stale: 1, Dead: 2}, body)
+}
@@ -0,0 +1,45 @@
+/*
+Copyright (C) 2018 Expedia Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pack
+
+import (
+	"net/http"
+
+	"github.com/HotelsDotCom/go-logger/logger"
+)
+
+// heartbeatClock lets tests stub "now" without waiting on the wall clock.
+var heartbeatClock clock = realClock{}
+
+// PostPackHeartbeat records that a pack is still alive. A pack calling this periodically stays
+// ALIVE; one that stops is demoted to STALE and then DEAD by the Reaper.
+var PostPackHeartbeat = Authenticate(postPackHeartbeat)
+
+func postPackHeartbeat(w http.ResponseWriter, r *http.Request) {
+
+	id := r.URL.Query().Get(":packId")
+	if err := packRepo.Heartbeat(id, heartbeatClock.Now()); err == PackNotFoundErr {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	} else if err != nil {
+		logger.Errorf("Cannot record heartbeat for packId=%s: %s", id, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
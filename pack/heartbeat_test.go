@@ -0,0 +1,74 @@
+/*
+Copyright (C) 2018 Expedia Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pack
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPostPackHeartbeat_ShouldRecordLastSeen(t *testing.T) {
+
+	defer resetPackRepo()
+	now := time.Date(2018, 1, 1, 12, 0, 0, 0, time.UTC)
+	defer func() { heartbeatClock = realClock{} }()
+	heartbeatClock = fixedClock{now}
+
+	packRepo = mockPackRepo{
+		get: func(id string) (*Pack, error) {
+			return &Pack{Id: "Slack", TokenHash: hashToken("s3cr3t")}, nil
+		},
+		heartbeat: func(id string, seenAt time.Time) error {
+			assert.Equal(t, "Slack", id)
+			assert.True(t, now.Equal(seenAt))
+			return nil
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/packs/Slack/heartbeat?:packId=Slack", nil)
+	req.Header.Set("Authorization", "Bearer s3cr3t")
+	w := httptest.NewRecorder()
+	PostPackHeartbeat(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Result().StatusCode)
+}
+
+func TestPostPackHeartbeat_Should404ForNonExistingPack(t *testing.T) {
+
+	defer resetPackRepo()
+	packRepo = mockPackRepo{
+		get: func(id string) (*Pack, error) {
+			return nil, PackNotFoundErr
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/packs/Slack/heartbeat?:packId=Slack", nil)
+	w := httptest.NewRecorder()
+	PostPackHeartbeat(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Result().StatusCode)
+}
+
+type fixedClock struct {
+	now time.Time
+}
+
+func (f fixedClock) Now() time.Time { return f.now }
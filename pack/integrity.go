@@ -0,0 +1,110 @@
+/*
+Copyright (C) 2018 Expedia Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pack
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/HotelsDotCom/flyte/action"
+	"github.com/HotelsDotCom/flyte/flow"
+)
+
+// ErrReferencedByPendingWork is returned when a PutPack/PatchPack would remove a command or
+// event that is still referenced by a pending action or a live flow step.
+var ErrReferencedByPendingWork = errors.New("pack update rejected: referenced by pending work")
+
+// pendingActionChecker is queried to see whether a command being removed from a pack still has
+// actions awaiting dispatch or in flight.
+type pendingActionChecker interface {
+	HasPendingActionsForCommand(packId, commandName string) (bool, error)
+}
+
+// liveStepChecker is queried to see whether an event being removed from a pack is still the
+// target of a live (non-terminal) flow step.
+type liveStepChecker interface {
+	HasLiveStepsForEvent(packId, eventName string) (bool, error)
+}
+
+var pendingActions pendingActionChecker = actionMgoChecker{}
+var liveSteps liveStepChecker = flowMgoChecker{}
+
+// checkReferentialIntegrity rejects an update that drops a command or event from current still
+// referenced by pending actions or live flow steps.
+func checkReferentialIntegrity(current *Pack, update Pack) error {
+
+	updatedCommands := commandNames(update.Commands)
+	for _, c := range current.Commands {
+		if updatedCommands[c.Name] {
+			continue
+		}
+		has, err := pendingActions.HasPendingActionsForCommand(current.Id, c.Name)
+		if err != nil {
+			return fmt.Errorf("cannot check pending actions for command=%s: %w", c.Name, err)
+		}
+		if has {
+			return fmt.Errorf("%w: command=%s", ErrReferencedByPendingWork, c.Name)
+		}
+	}
+
+	updatedEvents := eventNames(update.Events)
+	for _, e := range current.Events {
+		if updatedEvents[e.Name] {
+			continue
+		}
+		has, err := liveSteps.HasLiveStepsForEvent(current.Id, e.Name)
+		if err != nil {
+			return fmt.Errorf("cannot check live flow steps for event=%s: %w", e.Name, err)
+		}
+		if has {
+			return fmt.Errorf("%w: event=%s", ErrReferencedByPendingWork, e.Name)
+		}
+	}
+
+	return nil
+}
+
+func commandNames(commands []Command) map[string]bool {
+	names := make(map[string]bool, len(commands))
+	for _, c := range commands {
+		names[c.Name] = true
+	}
+	return names
+}
+
+func eventNames(events []Event) map[string]bool {
+	names := make(map[string]bool, len(events))
+	for _, e := range events {
+		names[e.Name] = true
+	}
+	return names
+}
+
+// actionMgoChecker is the production pendingActionChecker, backed by the action package's own
+// repository.
+type actionMgoChecker struct{}
+
+func (actionMgoChecker) HasPendingActionsForCommand(packId, commandName string) (bool, error) {
+	return action.PendingActionsExist(packId, commandName)
+}
+
+// flowMgoChecker is the production liveStepChecker, backed by the flow package's own repository.
+type flowMgoChecker struct{}
+
+func (flowMgoChecker) HasLiveStepsForEvent(packId, eventName string) (bool, error) {
+	return flow.LiveStepsReferenceEvent(packId, eventName)
+}
@@ -0,0 +1,93 @@
+/*
+Copyright (C) 2018 Expedia Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pack
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/HotelsDotCom/flyte/httputil"
+)
+
+const swaggerTakeActionRel = "/swagger#!/action/takeAction"
+const swaggerEventRel = "/swagger#/event"
+const swaggerPackRel = "/swagger#/pack"
+
+func (p *Pack) addLinks(r *http.Request) {
+
+	base := httputil.ProtocolAndHost(r)
+	self := fmt.Sprintf("%s/v1/packs/%s", base, p.Id)
+
+	for i := range p.Commands {
+		p.Commands[i].Links = []Link{
+			{Href: fmt.Sprintf("%s/actions/take?commandName=%s", self, p.Commands[i].Name), Rel: base + swaggerTakeActionRel},
+		}
+	}
+
+	p.Links = append(p.Links,
+		Link{Href: self, Rel: "self"},
+		Link{Href: base + "/v1/packs", Rel: "up"},
+	)
+	if len(p.Commands) > 0 {
+		p.Links = append(p.Links, Link{Href: self + "/actions/take", Rel: base + swaggerTakeActionRel})
+	}
+	if len(p.Events) > 0 {
+		p.Links = append(p.Links, Link{Href: self + "/events", Rel: base + swaggerEventRel})
+	}
+	p.Links = append(p.Links, Link{Href: self, Rel: "status"})
+}
+
+type packsResponse struct {
+	Links []Link      `json:"links"`
+	Packs []packEntry `json:"packs"`
+}
+
+type packEntry struct {
+	Id     string            `json:"id"`
+	Name   string            `json:"name"`
+	Labels map[string]string `json:"labels,omitempty"`
+	Status Status            `json:"status,omitempty"`
+	Links  []Link            `json:"links"`
+}
+
+func newPacksResponse(r *http.Request, packs []Pack) packsResponse {
+
+	base := httputil.ProtocolAndHost(r)
+	entries := make([]packEntry, len(packs))
+	for i, p := range packs {
+		self := fmt.Sprintf("%s/v1/packs/%s", base, p.Id)
+		entries[i] = packEntry{
+			Id:     p.Id,
+			Name:   p.Name,
+			Labels: p.Labels,
+			Status: p.Status,
+			Links: []Link{
+				{Href: self, Rel: "self"},
+				{Href: self, Rel: "status"},
+			},
+		}
+	}
+
+	return packsResponse{
+		Links: []Link{
+			{Href: base + "/v1/packs", Rel: "self"},
+			{Href: base + "/v1", Rel: "up"},
+			{Href: base + swaggerPackRel, Rel: "help"},
+		},
+		Packs: entries,
+	}
+}
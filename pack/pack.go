@@ -0,0 +1,86 @@
+/*
+Copyright (C) 2018 Expedia Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pack
+
+import (
+	"errors"
+	"time"
+)
+
+// PackNotFoundErr is returned by the packRepository when no pack exists for a given id.
+var PackNotFoundErr = errors.New("pack not found")
+
+// Pack represents a flyte pack registration: the commands it can take and the events it can raise.
+type Pack struct {
+	Id       string            `json:"id,omitempty" bson:"_id"`
+	Name     string            `json:"name"`
+	Labels   map[string]string `json:"labels,omitempty" bson:"labels,omitempty"`
+	Commands []Command         `json:"commands,omitempty" bson:"commands,omitempty"`
+	Events   []Event           `json:"events,omitempty" bson:"events,omitempty"`
+	// Links holds operator-supplied links (e.g. "help"); self/up/action/event links are added at
+	// render time by addLinks and are never persisted.
+	Links []Link `json:"links,omitempty" bson:"links,omitempty"`
+
+	// Version is incremented on every successful PutPack/PatchPack and used by packRepo.Update to
+	// compare-and-swap: a write whose Version doesn't match the stored document is rejected.
+	Version int `json:"version,omitempty" bson:"version"`
+
+	// AuthPolicy, when set, restricts which callers may act as this pack (see auth.go).
+	AuthPolicy *AuthPolicy `json:"authPolicy,omitempty" bson:"authPolicy,omitempty"`
+
+	// TokenHash is the unsalted SHA-256 hash of the per-pack API token minted on registration. The plaintext
+	// token is returned once in the PostPack response and is never persisted or returned again.
+	TokenHash string `json:"-" bson:"tokenHash,omitempty"`
+
+	// LastSeen is when the pack last called its heartbeat endpoint. Status is derived from it by
+	// the Reaper; a pack with no LastSeen yet (just registered) has the zero Status, treated as
+	// ALIVE.
+	LastSeen time.Time `json:"lastSeen,omitempty" bson:"lastSeen,omitempty"`
+	Status   Status    `json:"status,omitempty" bson:"status,omitempty"`
+}
+
+// Status is a pack's liveness, derived from how long ago it last heartbeat.
+type Status string
+
+const (
+	StatusAlive Status = "ALIVE"
+	StatusStale Status = "STALE"
+	StatusDead  Status = "DEAD"
+)
+
+// Command is an action a pack can take, and the events that can result from taking it.
+type Command struct {
+	Name   string   `json:"name"`
+	Events []string `json:"events,omitempty" bson:"events,omitempty"`
+	Links  []Link   `json:"links,omitempty" bson:"-"`
+}
+
+// Event is something a pack can raise.
+type Event struct {
+	Name string `json:"name"`
+}
+
+// Link is a HAL style link, as used throughout the flyte API.
+type Link struct {
+	Href string `json:"href"`
+	Rel  string `json:"rel"`
+}
+
+// generateId derives the pack id from its name. Pack ids are currently just the pack name.
+func (p *Pack) generateId() {
+	p.Id = p.Name
+}
@@ -0,0 +1,107 @@
+/*
+Copyright (C) 2018 Expedia Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pack
+
+import (
+	"context"
+	"time"
+
+	"github.com/HotelsDotCom/go-logger/logger"
+)
+
+// DefaultHeartbeatTTL is how long a pack may go without heartbeating before it is considered
+// STALE; it is DEAD after 3*DefaultHeartbeatTTL.
+const DefaultHeartbeatTTL = 30 * time.Second
+
+// clock abstracts time.Now so Reaper sweeps can be driven deterministically in tests.
+type clock interface {
+	Now() time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+// Reaper periodically demotes packs that have stopped heartbeating: to STALE after ttl, and to
+// DEAD after 3*ttl.
+type Reaper struct {
+	ttl    time.Duration
+	clock  clock
+	period time.Duration
+}
+
+// NewReaper builds a Reaper that sweeps for packs stale beyond ttl.
+func NewReaper(ttl time.Duration) *Reaper {
+	return newReaper(ttl, realClock{})
+}
+
+func newReaper(ttl time.Duration, clk clock) *Reaper {
+	period := ttl / 3
+	if period < time.Second {
+		period = time.Second
+	}
+	return &Reaper{ttl: ttl, clock: clk, period: period}
+}
+
+// Run sweeps on every tick until ctx is cancelled, at which point it stops its ticker and returns.
+func (re *Reaper) Run(ctx context.Context) {
+
+	ticker := time.NewTicker(re.period)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			re.sweep()
+		}
+	}
+}
+
+func (re *Reaper) sweep() {
+
+	packs, err := packRepo.FindAll()
+	if err != nil {
+		logger.Errorf("Cannot sweep packs for liveness: %s", err)
+		return
+	}
+
+	now := re.clock.Now()
+	for _, p := range packs {
+		if status := re.statusFor(p, now); status != p.Status {
+			if err := packRepo.UpdateStatus(p.Id, status); err != nil {
+				logger.Errorf("Cannot update status for packId=%s: %s", p.Id, err)
+			}
+		}
+	}
+}
+
+func (re *Reaper) statusFor(p Pack, now time.Time) Status {
+	if p.LastSeen.IsZero() {
+		// Just registered, never heartbeated yet: give it a chance to before judging it by age.
+		return StatusAlive
+	}
+	switch age := now.Sub(p.LastSeen); {
+	case age >= 3*re.ttl:
+		return StatusDead
+	case age >= re.ttl:
+		return StatusStale
+	default:
+		return StatusAlive
+	}
+}
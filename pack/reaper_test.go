@@ -0,0 +1,101 @@
+/*
+Copyright (C) 2018 Expedia Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pack
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReaper_SweepShouldMarkPacksStaleAndDead(t *testing.T) {
+
+	defer resetPackRepo()
+	now := time.Date(2018, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	statuses := map[string]Status{}
+	packRepo = mockPackRepo{
+		findAll: func() ([]Pack, error) {
+			return []Pack{
+				{Id: "fresh", LastSeen: now.Add(-5 * time.Second), Status: StatusAlive},
+				{Id: "stale", LastSeen: now.Add(-35 * time.Second), Status: StatusAlive},
+				{Id: "dead", LastSeen: now.Add(-95 * time.Second), Status: StatusStale},
+				{Id: "just-registered", Status: StatusAlive},
+			}, nil
+		},
+		updateStatus: func(id string, status Status) error {
+			statuses[id] = status
+			return nil
+		},
+	}
+
+	reaper := newReaper(30*time.Second, fixedClock{now})
+	reaper.sweep()
+
+	assert.Equal(t, map[string]Status{"stale": StatusStale, "dead": StatusDead}, statuses,
+		"fresh pack is already ALIVE so isn't re-written, and a just-registered pack with a zero LastSeen is ALIVE, not DEAD")
+}
+
+func TestReaper_StatusForShouldTreatZeroLastSeenAsAlive(t *testing.T) {
+
+	reaper := newReaper(30*time.Second, fixedClock{time.Now()})
+
+	status := reaper.statusFor(Pack{Id: "just-registered"}, time.Now())
+
+	assert.Equal(t, StatusAlive, status, "a pack that has never heartbeated yet has a zero LastSeen, not an infinite age")
+}
+
+func TestReaper_RunShouldStopWhenContextCancelled(t *testing.T) {
+
+	defer resetPackRepo()
+	swept := make(chan struct{}, 1)
+	packRepo = mockPackRepo{
+		findAll: func() ([]Pack, error) {
+			select {
+			case swept <- struct{}{}:
+			default:
+			}
+			return nil, nil
+		},
+	}
+
+	reaper := newReaper(3*time.Millisecond, fixedClock{time.Now()})
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan struct{})
+	go func() {
+		reaper.Run(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-swept:
+	// newReaper clamps the sweep period to at least one second, so allow for that first tick.
+	case <-time.After(3 * time.Second):
+		t.Fatal("reaper did not sweep before timeout")
+	}
+
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("reaper did not stop after context cancellation")
+	}
+}
@@ -0,0 +1,112 @@
+/*
+Copyright (C) 2018 Expedia Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pack
+
+import (
+	"errors"
+	"time"
+
+	"github.com/HotelsDotCom/flyte/repo"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+const packCollectionId = "pack"
+
+// ErrVersionConflict is returned by packRepository.Update when the caller's Pack.Version no
+// longer matches the stored document, i.e. the pack was updated concurrently.
+var ErrVersionConflict = errors.New("pack version conflict")
+
+// packRepository is the persistence boundary for packs. packMgoRepo is the production
+// implementation; tests substitute mockPackRepo.
+type packRepository interface {
+	Add(pack Pack) error
+	Update(pack Pack) error
+	Remove(id string) error
+	Get(id string) (*Pack, error)
+	FindAll() ([]Pack, error)
+
+	// Heartbeat marks a pack ALIVE as of seenAt. UpdateStatus transitions it to status without
+	// touching LastSeen; the Reaper uses it to demote packs that have stopped heartbeating.
+	Heartbeat(id string, seenAt time.Time) error
+	UpdateStatus(id string, status Status) error
+}
+
+var packRepo packRepository = packMgoRepo{}
+
+type packMgoRepo struct{}
+
+func (r packMgoRepo) Add(pack Pack) error {
+	return repo.GetCollection(packCollectionId).Insert(pack)
+}
+
+// Update compare-and-swaps pack by its Id and current Version, then bumps Version by one. A
+// document whose stored version doesn't match pack.Version (because it moved on, or doesn't
+// exist) causes ErrVersionConflict.
+func (r packMgoRepo) Update(pack Pack) error {
+	pack.Version++
+	err := repo.GetCollection(packCollectionId).Update(
+		bson.M{"_id": pack.Id, "version": pack.Version - 1},
+		pack,
+	)
+	if err == mgo.ErrNotFound {
+		return ErrVersionConflict
+	}
+	return err
+}
+
+func (r packMgoRepo) Remove(id string) error {
+	err := repo.GetCollection(packCollectionId).RemoveId(id)
+	if err == mgo.ErrNotFound {
+		return PackNotFoundErr
+	}
+	return err
+}
+
+func (r packMgoRepo) Get(id string) (*Pack, error) {
+	pack := &Pack{}
+	err := repo.GetCollection(packCollectionId).FindId(id).One(pack)
+	if err == mgo.ErrNotFound {
+		return nil, PackNotFoundErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return pack, nil
+}
+
+func (r packMgoRepo) FindAll() ([]Pack, error) {
+	packs := []Pack{}
+	err := repo.GetCollection(packCollectionId).Find(nil).All(&packs)
+	return packs, err
+}
+
+func (r packMgoRepo) Heartbeat(id string, seenAt time.Time) error {
+	err := repo.GetCollection(packCollectionId).UpdateId(id, bson.M{"$set": bson.M{"lastSeen": seenAt, "status": StatusAlive}})
+	if err == mgo.ErrNotFound {
+		return PackNotFoundErr
+	}
+	return err
+}
+
+func (r packMgoRepo) UpdateStatus(id string, status Status) error {
+	err := repo.GetCollection(packCollectionId).UpdateId(id, bson.M{"$set": bson.M{"status": status}})
+	if err == mgo.ErrNotFound {
+		return PackNotFoundErr
+	}
+	return err
+}
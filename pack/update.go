@@ -0,0 +1,104 @@
+/*
+Copyright (C) 2018 Expedia Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pack
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/HotelsDotCom/flyte/event"
+	"github.com/HotelsDotCom/go-logger/logger"
+)
+
+// PutPack updates a running pack's commands, events, labels and links in place, without a
+// delete-then-recreate cycle. PatchPack is an alias: flyte updates always replace the whole
+// document (guarded by Authenticate and the version compare-and-swap in packRepo.Update), so
+// there is no partial-patch semantics to distinguish from a full put.
+var PutPack = Authenticate(putPack)
+var PatchPack = PutPack
+
+func putPack(w http.ResponseWriter, r *http.Request) {
+
+	id := r.URL.Query().Get(":packId")
+
+	update := Pack{}
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		logger.Errorf("Cannot convert request to pack: %s", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	update.Id = id
+
+	current, err := packRepo.Get(id)
+	if err == PackNotFoundErr {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		logger.Errorf("Cannot find packId=%s: %s", id, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	// Audited from here on: every attempt to change this pack, successful or not, is a fact about
+	// who tried to change what and when.
+	auditSink.PackUpdated(id, actorFor(r, id), r.RemoteAddr, time.Now())
+
+	if err := checkReferentialIntegrity(current, update); err != nil {
+		if errors.Is(err, ErrReferencedByPendingWork) {
+			logger.Errorf("Cannot update packId=%s: %s", id, err)
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		logger.Errorf("Cannot update packId=%s: %s", id, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	// AuthPolicy, the token hash and the liveness fields are all managed outside PutPack/PatchPack
+	// (by their own endpoints, or by the Reaper/heartbeat) - carry them over untouched so a config
+	// update can't reset a pack's liveness status to zero and have the Reaper mark it DEAD.
+	update.AuthPolicy = current.AuthPolicy
+	update.TokenHash = current.TokenHash
+	update.LastSeen = current.LastSeen
+	update.Status = current.Status
+
+	if err := packRepo.Update(update); err == ErrVersionConflict {
+		w.WriteHeader(http.StatusConflict)
+		return
+	} else if err != nil {
+		logger.Errorf("Cannot update packId=%s: %s", id, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	publishPackUpdated(update.Id)
+
+	update.Version++
+	update.addLinks(r)
+	writeJson(w, http.StatusOK, update)
+}
+
+// publishPackUpdated is a package-level var so tests can substitute it; in production it notifies
+// the flow engine to re-resolve step targets against the pack's new command/event list.
+var publishPackUpdated = defaultPublishPackUpdated
+
+func defaultPublishPackUpdated(packId string) {
+	event.Publish(event.Event{Name: "pack.updated", Host: packId})
+}
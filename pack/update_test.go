@@ -0,0 +1,200 @@
+/*
+Copyright (C) 2018 Expedia Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pack
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPutPack_ShouldUpdatePackForValidRequest(t *testing.T) {
+
+	defer resetPackRepo()
+	defer resetIntegrityCheckers()
+	defer func() { publishPackUpdated = defaultPublishPackUpdated }()
+
+	published := ""
+	publishPackUpdated = func(packId string) { published = packId }
+
+	current := &Pack{Id: "Slack", Name: "Slack", TokenHash: hashToken("s3cr3t"), Version: 1,
+		Commands: []Command{{Name: "SendMessage", Events: []string{"MessageSent"}}}}
+
+	var updatedPack Pack
+	packRepo = mockPackRepo{
+		get: func(id string) (*Pack, error) { return current, nil },
+		update: func(pack Pack) error {
+			updatedPack = pack
+			return nil
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodPut, "/v1/packs/Slack?:packId=Slack", strings.NewReader(putPackRequest))
+	req.Header.Set("Authorization", "Bearer s3cr3t")
+	w := httptest.NewRecorder()
+	PutPack(w, req)
+
+	resp := w.Result()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "Slack", published)
+	assert.Equal(t, 1, updatedPack.Version, "repo.Update receives the client's current version, and is responsible for bumping it")
+	assert.Len(t, updatedPack.Commands, 1)
+	assert.Equal(t, "SendMessage", updatedPack.Commands[0].Name)
+}
+
+func TestPutPack_ShouldReturn409_OnVersionConflict(t *testing.T) {
+
+	defer resetPackRepo()
+	defer resetIntegrityCheckers()
+
+	current := &Pack{Id: "Slack", Name: "Slack", TokenHash: hashToken("s3cr3t"), Version: 1}
+	packRepo = mockPackRepo{
+		get: func(id string) (*Pack, error) { return current, nil },
+		update: func(pack Pack) error {
+			return ErrVersionConflict
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodPut, "/v1/packs/Slack?:packId=Slack", strings.NewReader(putPackRequest))
+	req.Header.Set("Authorization", "Bearer s3cr3t")
+	w := httptest.NewRecorder()
+	PutPack(w, req)
+
+	resp := w.Result()
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+}
+
+func TestPutPack_ShouldReturn409_WhenRemovingCommandWithPendingActions(t *testing.T) {
+
+	defer resetPackRepo()
+	defer resetIntegrityCheckers()
+
+	current := &Pack{Id: "Slack", Name: "Slack", TokenHash: hashToken("s3cr3t"), Version: 1,
+		Commands: []Command{{Name: "SendMessage"}, {Name: "DeleteMessage"}}}
+	packRepo = mockPackRepo{
+		get: func(id string) (*Pack, error) { return current, nil },
+	}
+	pendingActions = fakePendingActionChecker{hasPending: map[string]bool{"DeleteMessage": true}}
+
+	req := httptest.NewRequest(http.MethodPut, "/v1/packs/Slack?:packId=Slack", strings.NewReader(putPackRequest))
+	req.Header.Set("Authorization", "Bearer s3cr3t")
+	w := httptest.NewRecorder()
+	PutPack(w, req)
+
+	resp := w.Result()
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+}
+
+func TestPutPack_ShouldReturn409_WhenRemovingEventWithLiveFlowSteps(t *testing.T) {
+
+	defer resetPackRepo()
+	defer resetIntegrityCheckers()
+
+	current := &Pack{Id: "Slack", Name: "Slack", TokenHash: hashToken("s3cr3t"), Version: 1,
+		Commands: []Command{{Name: "SendMessage", Events: []string{"MessageSent"}}},
+		Events:   []Event{{Name: "MessageSent"}, {Name: "MessageFailed"}}}
+	packRepo = mockPackRepo{
+		get: func(id string) (*Pack, error) { return current, nil },
+	}
+	liveSteps = fakeLiveStepChecker{hasLive: map[string]bool{"MessageFailed": true}}
+
+	req := httptest.NewRequest(http.MethodPut, "/v1/packs/Slack?:packId=Slack", strings.NewReader(putPackRequest))
+	req.Header.Set("Authorization", "Bearer s3cr3t")
+	w := httptest.NewRecorder()
+	PutPack(w, req)
+
+	resp := w.Result()
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+}
+
+func TestPutPack_Should404ForNonExistingPack(t *testing.T) {
+
+	defer resetPackRepo()
+	defer resetIntegrityCheckers()
+
+	packRepo = mockPackRepo{
+		get: func(id string) (*Pack, error) { return nil, PackNotFoundErr },
+	}
+
+	req := httptest.NewRequest(http.MethodPut, "/v1/packs/Slack?:packId=Slack", strings.NewReader(putPackRequest))
+	w := httptest.NewRecorder()
+	PutPack(w, req)
+
+	resp := w.Result()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestPutPack_ShouldReturn400ForInvalidRequest(t *testing.T) {
+
+	defer resetPackRepo()
+	defer resetIntegrityCheckers()
+
+	packRepo = mockPackRepo{
+		get: func(id string) (*Pack, error) { return &Pack{Id: "Slack", TokenHash: hashToken("s3cr3t")}, nil },
+	}
+
+	req := httptest.NewRequest(http.MethodPut, "/v1/packs/Slack?:packId=Slack", strings.NewReader(`--- invalid json ---`))
+	req.Header.Set("Authorization", "Bearer s3cr3t")
+	w := httptest.NewRecorder()
+	PutPack(w, req)
+
+	resp := w.Result()
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+var putPackRequest = `
+{
+    "name": "Slack",
+    "version": 1,
+    "commands": [
+        {
+            "name": "SendMessage",
+            "events": ["MessageSent"]
+        }
+    ],
+    "events": [
+        {
+            "name": "MessageSent"
+        }
+    ]
+}
+`
+
+type fakePendingActionChecker struct {
+	hasPending map[string]bool
+}
+
+func (f fakePendingActionChecker) HasPendingActionsForCommand(packId, commandName string) (bool, error) {
+	return f.hasPending[commandName], nil
+}
+
+type fakeLiveStepChecker struct {
+	hasLive map[string]bool
+}
+
+func (f fakeLiveStepChecker) HasLiveStepsForEvent(packId, eventName string) (bool, error) {
+	return f.hasLive[eventName], nil
+}
+
+func resetIntegrityCheckers() {
+	pendingActions = actionMgoChecker{}
+	liveSteps = flowMgoChecker{}
+}